@@ -0,0 +1,77 @@
+// Copyright 2023 Greptime Team
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package greptime
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Stop is returned by a BackOff once it has exhausted its retries,
+// matching github.com/cenkalti/backoff/v4.Stop.
+const Stop time.Duration = -1
+
+// BackOff is the retry policy interface Writer uses to space out retries.
+// It intentionally matches github.com/cenkalti/backoff/v4's BackOff
+// interface, so a caller can pass that package's implementations (or
+// backoff.WithMaxRetries, backoff.WithContext, ...) directly instead of
+// ExponentialBackOff.
+type BackOff interface {
+	// NextBackOff returns the duration to wait before the next retry, or
+	// Stop if no more retries should be attempted.
+	NextBackOff() time.Duration
+}
+
+// ExponentialBackOff is the default BackOff: the delay doubles from
+// InitialInterval up to MaxInterval, with up to 50% jitter, until
+// MaxRetries attempts have been made.
+type ExponentialBackOff struct {
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	MaxRetries      int
+
+	attempt int
+}
+
+// NewExponentialBackOff creates an ExponentialBackOff with sane defaults.
+func NewExponentialBackOff() *ExponentialBackOff {
+	return &ExponentialBackOff{
+		InitialInterval: 100 * time.Millisecond,
+		MaxInterval:     10 * time.Second,
+		MaxRetries:      5,
+	}
+}
+
+// NextBackOff implements BackOff.
+func (b *ExponentialBackOff) NextBackOff() time.Duration {
+	if b.attempt >= b.MaxRetries {
+		return Stop
+	}
+
+	d := b.InitialInterval * time.Duration(int64(1)<<uint(b.attempt))
+	if d <= 0 || d > b.MaxInterval {
+		d = b.MaxInterval
+	}
+	b.attempt++
+
+	jitter := time.Duration(rand.Int63n(int64(d)/2 + 1))
+	return d/2 + jitter
+}
+
+// Reset allows an ExponentialBackOff to be reused for a new sequence of
+// retries.
+func (b *ExponentialBackOff) Reset() {
+	b.attempt = 0
+}
@@ -0,0 +1,41 @@
+// Copyright 2023 Greptime Team
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package greptime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExponentialBackOff(t *testing.T) {
+	b := &ExponentialBackOff{
+		InitialInterval: time.Millisecond,
+		MaxInterval:     time.Second,
+		MaxRetries:      3,
+	}
+
+	for i := 0; i < 3; i++ {
+		d := b.NextBackOff()
+		assert.NotEqual(t, Stop, d)
+		assert.LessOrEqual(t, d, b.MaxInterval)
+	}
+
+	assert.Equal(t, Stop, b.NextBackOff())
+
+	b.Reset()
+	assert.NotEqual(t, Stop, b.NextBackOff())
+}
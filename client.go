@@ -0,0 +1,154 @@
+// Copyright 2023 Greptime Team
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package greptime
+
+import (
+	"context"
+
+	greptimepb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+	"github.com/apache/arrow/go/arrow/flight"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// Client is the entrypoint to interact with GreptimeDB: inserting Metrics
+// and running Sql/PromQL queries.
+type Client struct {
+	cfg *Config
+
+	conn        *grpc.ClientConn
+	database    greptimepb.GreptimeDatabaseClient
+	promGateway greptimepb.PrometheusGatewayClient
+	flight      flight.Client
+}
+
+// NewClient dials GreptimeDB according to cfg and returns a ready-to-use
+// Client.
+func NewClient(cfg *Config) (*Client, error) {
+	conn, err := grpc.Dial(cfg.addr(), grpc.WithInsecure())
+	if err != nil {
+		return nil, err
+	}
+
+	flightClient, err := flight.NewClientWithMiddleware(cfg.addr(), nil, nil, grpc.WithInsecure())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		cfg:         cfg,
+		conn:        conn,
+		database:    greptimepb.NewGreptimeDatabaseClient(conn),
+		promGateway: greptimepb.NewPrometheusGatewayClient(conn),
+		flight:      flightClient,
+	}, nil
+}
+
+// Insert writes r into GreptimeDB and returns the number of affected rows.
+func (c *Client) Insert(ctx context.Context, r *InsertRequest) (uint32, error) {
+	req, err := r.build(c.cfg)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := c.database.Handle(ctx, req)
+	if err != nil {
+		return 0, err
+	}
+
+	return resp.GetAffectedRows().GetValue(), nil
+}
+
+// Query runs r (Sql or RangePromql) against GreptimeDB and parses the
+// result into a Metric. A QueryRequest carrying only an InstantPromql is
+// rejected with ErrInstantPromqlUnsupported; PromqlInstant runs those.
+func (c *Client) Query(ctx context.Context, r *QueryRequest) (*Metric, error) {
+	req, err := r.buildGreptimeRequest(c.cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.doGet(ctx, req)
+}
+
+// PromqlInstant runs an instant PromQL query against GreptimeDB's
+// PrometheusGateway and returns the raw response body. Unlike Query and
+// PromqlRange, this does not go through Flight: GreptimeDB has no way to
+// carry an instant PromQL query in a QueryRequest, so it is served by a
+// separate PrometheusGateway.Handle rpc whose response is not Arrow-shaped.
+func (c *Client) PromqlInstant(ctx context.Context, database string, promql *InstantPromql) (*PromqlResult, error) {
+	r := (&QueryRequest{}).WithDatabase(database).WithInstantPromql(promql)
+
+	req, err := r.buildPromqlRequest(c.cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.promGateway.Handle(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PromqlResult{Body: resp.GetBody()}, nil
+}
+
+// PromqlRange runs a range PromQL query against GreptimeDB and parses the
+// result into a Metric. Unlike PromqlInstant, GreptimeDB's QueryRequest can
+// carry a range PromQL query directly, so this goes through the same
+// Flight/doGet path as Query.
+func (c *Client) PromqlRange(ctx context.Context, database string, promql *RangePromql) (*Metric, error) {
+	r := (&QueryRequest{}).WithDatabase(database).WithRangePromql(promql)
+
+	req, err := r.buildGreptimeRequest(c.cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.doGet(ctx, req)
+}
+
+// doGet sends req as a Flight ticket and decodes the streamed Arrow
+// RecordBatch into a Metric, the same way buildMetricFromReader does for
+// any other query.
+func (c *Client) doGet(ctx context.Context, req *greptimepb.GreptimeRequest) (*Metric, error) {
+	ticket, err := proto.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := c.flight.DoGet(ctx, &flight.Ticket{Ticket: ticket})
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := flight.NewRecordReader(stream)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Release()
+
+	return buildMetricFromReader(reader)
+}
+
+// Close releases the underlying gRPC connections, including the separate
+// Flight connection opened for Query/PromqlInstant/PromqlRange/
+// NewStreamWriter.
+func (c *Client) Close() error {
+	flightErr := c.flight.Close()
+	if err := c.conn.Close(); err != nil {
+		return err
+	}
+	return flightErr
+}
@@ -0,0 +1,76 @@
+// Copyright 2023 Greptime Team
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build integration
+
+package greptime
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestClientPromqlInstant requires a running GreptimeDB reachable at
+// GREPTIMEDB_ENDPOINT (host:port). Run with `go test -tags=integration`.
+func TestClientPromqlInstant(t *testing.T) {
+	endpoint := os.Getenv("GREPTIMEDB_ENDPOINT")
+	if isEmptyString(endpoint) {
+		t.Skip("GREPTIMEDB_ENDPOINT is not set, skip integration test")
+	}
+
+	cfg := NewConfig(endpoint).WithDatabase("public")
+	client, err := NewClient(cfg)
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := client.PromqlInstant(ctx, "public", NewInstantPromql("up == 0"))
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.NotEmpty(t, result.Body)
+}
+
+// TestClientPromqlRange requires a running GreptimeDB reachable at
+// GREPTIMEDB_ENDPOINT (host:port). Run with `go test -tags=integration`.
+func TestClientPromqlRange(t *testing.T) {
+	endpoint := os.Getenv("GREPTIMEDB_ENDPOINT")
+	if isEmptyString(endpoint) {
+		t.Skip("GREPTIMEDB_ENDPOINT is not set, skip integration test")
+	}
+
+	cfg := NewConfig(endpoint).WithDatabase("public")
+	client, err := NewClient(cfg)
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rp := &RangePromql{
+		Query: "up == 0",
+		Start: time.Now().Add(-time.Hour),
+		End:   time.Now(),
+		Step:  time.Minute,
+	}
+	metric, err := client.PromqlRange(ctx, "public", rp)
+	assert.NoError(t, err)
+	assert.NotNil(t, metric)
+}
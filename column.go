@@ -0,0 +1,67 @@
+// Copyright 2023 Greptime Team
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package greptime
+
+import (
+	"fmt"
+
+	greptimepb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+)
+
+// column is the schema of one column inside a Metric: its semantic role
+// (tag, field or timestamp) and its wire datatype.
+type column struct {
+	semantic greptimepb.SemanticType
+	typ      greptimepb.ColumnDataType
+}
+
+// checkColumnEquality makes sure a column name always refers to the same
+// schema across every Series added to the same Metric.
+func checkColumnEquality(name string, a, b column) error {
+	if a.semantic != b.semantic {
+		return fmt.Errorf("conflict semantic type of column '%s': '%s' and '%s'", name, a.semantic, b.semantic)
+	}
+	if a.typ != b.typ {
+		return fmt.Errorf("conflict datatype of column '%s': '%s' and '%s'", name, a.typ, b.typ)
+	}
+	return nil
+}
+
+// mask is a growable bitset recording which rows are null for one column.
+type mask struct {
+	bytes []byte
+}
+
+func (m *mask) set(idx uint) {
+	byteIdx := idx / 8
+	for uint(len(m.bytes)) <= byteIdx {
+		m.bytes = append(m.bytes, 0)
+	}
+	m.bytes[byteIdx] |= 1 << (idx % 8)
+}
+
+// shrink pads or truncates the mask to size bytes, matching the row count
+// of the owning Metric.
+func (m *mask) shrink(size int) ([]byte, error) {
+	if size < 0 {
+		return nil, fmt.Errorf("invalid null mask size '%d'", size)
+	}
+	if len(m.bytes) >= size {
+		return m.bytes[:size], nil
+	}
+	b := make([]byte, size)
+	copy(b, m.bytes)
+	return b, nil
+}
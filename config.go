@@ -0,0 +1,78 @@
+// Copyright 2023 Greptime Team
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package greptime
+
+import (
+	"fmt"
+
+	greptimepb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+)
+
+// defaultPort is the default gRPC port GreptimeDB listens on.
+const defaultPort = 4001
+
+// Config holds the information needed to connect to GreptimeDB, plus the
+// default database used by requests that do not specify one explicitly.
+type Config struct {
+	Host     string
+	Port     int
+	Database string
+
+	Username string
+	Password string
+}
+
+// NewConfig creates a Config pointing at host, using the default gRPC port.
+func NewConfig(host string) *Config {
+	return &Config{Host: host, Port: defaultPort}
+}
+
+// WithDatabase sets the default database name.
+func (c *Config) WithDatabase(database string) *Config {
+	c.Database = database
+	return c
+}
+
+// WithPort overrides the default gRPC port.
+func (c *Config) WithPort(port int) *Config {
+	c.Port = port
+	return c
+}
+
+// WithAuth sets the username/password used for basic authentication.
+func (c *Config) WithAuth(username, password string) *Config {
+	c.Username = username
+	c.Password = password
+	return c
+}
+
+func (c *Config) addr() string {
+	return fmt.Sprintf("%s:%d", c.Host, c.Port)
+}
+
+func (c *Config) buildAuthHeader() *greptimepb.AuthHeader {
+	if isEmptyString(c.Username) {
+		return nil
+	}
+
+	return &greptimepb.AuthHeader{
+		AuthScheme: &greptimepb.AuthHeader_Basic{
+			Basic: &greptimepb.Basic{
+				Username: c.Username,
+				Password: c.Password,
+			},
+		},
+	}
+}
@@ -19,17 +19,18 @@ import (
 )
 
 var (
-	ErrEmptyDatabase        = errors.New("name of database should not be empty")
-	ErrEmptyTable           = errors.New("name of table should not be be empty")
-	ErrEmptyTimestamp       = errors.New("timestamp should not be empty")
-	ErrEmptyQuery           = errors.New("query should not be empty, assign Sql, InstantPromql or RangePromql")
-	ErrEmptyKey             = errors.New("key should not be empty")
-	ErrEmptySql             = errors.New("sql is required in querying")
-	ErrEmptyPromql          = errors.New("promql is required in promql querying")
-	ErrEmptyStep            = errors.New("step is required in range promql")
-	ErrEmptyRange           = errors.New("start and end is required in range promql")
-	ErrInvalidTimePrecision = errors.New("precision of timestamp is not valid")
-	ErrNoSeriesInMetric     = errors.New("empty series in Metric")
-	ErrNotImplemented       = errors.New("not implemented!")
-	ErrSqlInPromql          = errors.New("Sql can not be used as Promql")
+	ErrEmptyDatabase            = errors.New("name of database should not be empty")
+	ErrEmptyTable               = errors.New("name of table should not be be empty")
+	ErrEmptyTimestamp           = errors.New("timestamp should not be empty")
+	ErrEmptyQuery               = errors.New("query should not be empty, assign Sql, InstantPromql or RangePromql")
+	ErrEmptyKey                 = errors.New("key should not be empty")
+	ErrEmptySql                 = errors.New("sql is required in querying")
+	ErrEmptyPromql              = errors.New("promql is required in promql querying")
+	ErrEmptyStep                = errors.New("step is required in range promql")
+	ErrEmptyRange               = errors.New("start and end is required in range promql")
+	ErrInvalidTimePrecision     = errors.New("precision of timestamp is not valid")
+	ErrNoSeriesInMetric         = errors.New("empty series in Metric")
+	ErrNotImplemented           = errors.New("not implemented!")
+	ErrSqlInPromql              = errors.New("Sql can not be used as Promql")
+	ErrInstantPromqlUnsupported = errors.New("InstantPromql is not supported by Client.Query; use Client.PromqlInstant")
 )
@@ -0,0 +1,61 @@
+// Copyright 2023 Greptime Team
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package greptime
+
+import (
+	"time"
+
+	greptimepb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+)
+
+func isEmptyString(s string) bool {
+	return len(s) == 0
+}
+
+// toColumnName validates name as a column/table identifier, returning
+// ErrEmptyKey if it is empty.
+func toColumnName(name string) (string, error) {
+	if isEmptyString(name) {
+		return "", ErrEmptyKey
+	}
+	return name, nil
+}
+
+// isValidPrecision reports whether precision is one of the durations
+// GreptimeDB accepts for a timestamp column.
+func isValidPrecision(precision time.Duration) bool {
+	switch precision {
+	case time.Second, time.Millisecond, time.Microsecond, time.Nanosecond:
+		return true
+	default:
+		return false
+	}
+}
+
+// precisionToDataType maps a timestamp precision to its wire datatype.
+func precisionToDataType(precision time.Duration) (greptimepb.ColumnDataType, error) {
+	switch precision {
+	case time.Second:
+		return greptimepb.ColumnDataType_TIMESTAMP_SECOND, nil
+	case time.Millisecond:
+		return greptimepb.ColumnDataType_TIMESTAMP_MILLISECOND, nil
+	case time.Microsecond:
+		return greptimepb.ColumnDataType_TIMESTAMP_MICROSECOND, nil
+	case time.Nanosecond:
+		return greptimepb.ColumnDataType_TIMESTAMP_NANOSECOND, nil
+	default:
+		return 0, ErrInvalidTimePrecision
+	}
+}
@@ -0,0 +1,390 @@
+// Copyright 2023 Greptime Team
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package greptime
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LineProtocolOptions configures ParseLineProtocol.
+type LineProtocolOptions struct {
+	precision time.Duration
+}
+
+// NewLineProtocolOptions creates LineProtocolOptions defaulting to
+// nanosecond timestamps, the precision Telegraf and the InfluxDB line
+// protocol assume unless told otherwise.
+func NewLineProtocolOptions() *LineProtocolOptions {
+	return &LineProtocolOptions{precision: time.Nanosecond}
+}
+
+// WithPrecision overrides the unit the trailing integer timestamp of each
+// line is interpreted in.
+func (o *LineProtocolOptions) WithPrecision(precision time.Duration) *LineProtocolOptions {
+	o.precision = precision
+	return o
+}
+
+// ParseLineProtocol reads Telegraf/InfluxDB line protocol from r,
+//
+//	measurement,tag1=v1,tag2=v2 field1=1i,field2=2.0,field3="s",field4=t <ts>
+//
+// and turns every measurement into an InsertRequest targeting a table of
+// the same name: tags become TAG columns, fields become FIELD columns and
+// the trailing timestamp becomes the timestamp column. Lines belonging to
+// the same measurement are merged into a single InsertRequest, relying on
+// Metric.AddSeries to union their columns. Comment ("#...") and blank
+// lines are skipped.
+func ParseLineProtocol(r io.Reader, opts *LineProtocolOptions) ([]*InsertRequest, error) {
+	if opts == nil {
+		opts = NewLineProtocolOptions()
+	}
+
+	requests := map[string]*InsertRequest{}
+	var order []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if isEmptyString(line) || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		table, series, err := parseLine(line, opts.precision)
+		if err != nil {
+			return nil, fmt.Errorf("parse line protocol: %w", err)
+		}
+
+		req, seen := requests[table]
+		if !seen {
+			req = (&InsertRequest{}).WithTable(table)
+			if err := req.SetTimePrecision(time.Nanosecond); err != nil {
+				return nil, err
+			}
+			requests[table] = req
+			order = append(order, table)
+		}
+
+		if err := req.AddSeries(series); err != nil {
+			return nil, fmt.Errorf("parse line protocol for table '%s': %w", table, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make([]*InsertRequest, 0, len(order))
+	for _, table := range order {
+		result = append(result, requests[table])
+	}
+	return result, nil
+}
+
+// WriteLineProtocol parses r as line protocol and inserts every resulting
+// table into database, returning the total number of affected rows.
+func (c *Client) WriteLineProtocol(ctx context.Context, database string, r io.Reader, opts *LineProtocolOptions) (uint32, error) {
+	requests, err := ParseLineProtocol(r, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	var affected uint32
+	for _, req := range requests {
+		req.WithDatabase(database)
+		n, err := c.Insert(ctx, req)
+		if err != nil {
+			return affected, err
+		}
+		affected += n
+	}
+	return affected, nil
+}
+
+func parseLine(line string, precision time.Duration) (string, Series, error) {
+	seriesPart, fieldsPart, tsPart, err := splitLineProtocolLine(line)
+	if err != nil {
+		return "", Series{}, err
+	}
+
+	table, tags, err := parseLineProtocolSeries(seriesPart)
+	if err != nil {
+		return "", Series{}, err
+	}
+
+	var series Series
+	for _, tag := range tags {
+		series.AddTag(tag.key, tag.value)
+	}
+
+	fields, err := parseLineProtocolFields(fieldsPart)
+	if err != nil {
+		return "", Series{}, err
+	}
+	if len(fields) == 0 {
+		return "", Series{}, fmt.Errorf("line has no fields: %q", line)
+	}
+	for _, field := range fields {
+		if err := series.AddField(field.key, field.value); err != nil {
+			return "", Series{}, err
+		}
+	}
+
+	ts := time.Now()
+	if !isEmptyString(tsPart) {
+		n, err := strconv.ParseInt(tsPart, 10, 64)
+		if err != nil {
+			return "", Series{}, fmt.Errorf("invalid timestamp %q: %w", tsPart, err)
+		}
+		ts = time.Unix(0, n*int64(precision))
+	}
+	series.SetTimestamp(ts)
+
+	return table, series, nil
+}
+
+// splitLineProtocolLine splits a line into its series (measurement+tags),
+// fields and timestamp parts on the first two unescaped, unquoted spaces.
+func splitLineProtocolLine(line string) (series, fields, ts string, err error) {
+	var splits []int
+	escaped := false
+	inQuotes := false
+	for i, r := range line {
+		if escaped {
+			escaped = false
+			continue
+		}
+		switch r {
+		case '\\':
+			escaped = true
+		case '"':
+			inQuotes = !inQuotes
+		case ' ':
+			if !inQuotes {
+				splits = append(splits, i)
+			}
+		}
+	}
+
+	switch len(splits) {
+	case 0:
+		return "", "", "", fmt.Errorf("line is missing fields: %q", line)
+	case 1:
+		return line[:splits[0]], line[splits[0]+1:], "", nil
+	default:
+		return line[:splits[0]], line[splits[0]+1 : splits[1]], strings.TrimSpace(line[splits[1]+1:]), nil
+	}
+}
+
+type lineProtocolKV struct {
+	key   string
+	value string
+}
+
+type lineProtocolField struct {
+	key   string
+	value any
+}
+
+// parseLineProtocolSeries splits "measurement,tag1=v1,tag2=v2" into the
+// measurement name and its tags, unescaping `\,`, `\ ` and `\=`.
+//
+// Splitting on ',' and '=' happens in two passes (measurement/tags, then
+// key/value), so the comma split must leave `\=` untouched - otherwise an
+// escaped '=' inside a tag value (e.g. `loc=us\=east`) would already look
+// like a bare '=' by the time the second pass runs. splitLineProtocolRaw
+// preserves every escape sequence it isn't splitting on, and each token is
+// unescaped only once, after both passes have resolved its delimiters.
+func parseLineProtocolSeries(s string) (string, []lineProtocolKV, error) {
+	tokens := splitLineProtocolRaw(s, ',', false)
+	if len(tokens) == 0 || isEmptyString(tokens[0]) {
+		return "", nil, fmt.Errorf("missing measurement name in %q", s)
+	}
+
+	table, err := toColumnName(lineProtocolUnescape(tokens[0]))
+	if err != nil {
+		return "", nil, err
+	}
+
+	tags := make([]lineProtocolKV, 0, len(tokens)-1)
+	for _, tok := range tokens[1:] {
+		parts := splitLineProtocolRaw(tok, '=', false)
+		if len(parts) != 2 {
+			return "", nil, fmt.Errorf("invalid tag %q in %q", tok, s)
+		}
+
+		key, err := toColumnName(lineProtocolUnescape(parts[0]))
+		if err != nil {
+			return "", nil, err
+		}
+		tags = append(tags, lineProtocolKV{key: key, value: lineProtocolUnescape(parts[1])})
+	}
+
+	return table, tags, nil
+}
+
+// parseLineProtocolFields splits `field1=1i,field2=2.0,field3="a,b"` into
+// typed field values, respecting quoted strings so that commas (or an
+// unescaped '=') inside them do not split fields.
+func parseLineProtocolFields(s string) ([]lineProtocolField, error) {
+	var fields []lineProtocolField
+	for _, raw := range splitLineProtocolRaw(s, ',', true) {
+		key, value, err := splitLineProtocolKeyValue(raw, true)
+		if err != nil {
+			return nil, err
+		}
+
+		name, err := toColumnName(key)
+		if err != nil {
+			return nil, err
+		}
+
+		val, err := parseLineProtocolFieldValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("field '%s': %w", name, err)
+		}
+
+		fields = append(fields, lineProtocolField{key: name, value: val})
+	}
+	return fields, nil
+}
+
+// splitLineProtocolKeyValue splits "key=value" on the first unescaped '='
+// that isn't inside a quoted value, and unescapes the key. The value is
+// returned as-is: parseLineProtocolFieldValue unescapes quoted string
+// values itself, and the other value kinds never contain escapes.
+func splitLineProtocolKeyValue(s string, quoteAware bool) (string, string, error) {
+	escaped := false
+	inQuotes := false
+	for i, r := range s {
+		if escaped {
+			escaped = false
+			continue
+		}
+		switch {
+		case r == '\\':
+			escaped = true
+		case quoteAware && r == '"':
+			inQuotes = !inQuotes
+		case r == '=' && !inQuotes:
+			return lineProtocolUnescape(s[:i]), s[i+1:], nil
+		}
+	}
+	return "", "", fmt.Errorf("missing '=' in %q", s)
+}
+
+// parseLineProtocolFieldValue parses one field's raw value, detecting its
+// type from its suffix/quoting: `i` for int64, `u` for uint64, `t/f` (and
+// spellings thereof) for bool, a quoted string for STRING, anything else
+// for float64.
+func parseLineProtocolFieldValue(raw string) (any, error) {
+	if len(raw) >= 2 && raw[0] == '"' && raw[len(raw)-1] == '"' {
+		return lineProtocolUnescape(raw[1 : len(raw)-1]), nil
+	}
+
+	switch raw {
+	case "t", "T", "true", "True", "TRUE":
+		return true, nil
+	case "f", "F", "false", "False", "FALSE":
+		return false, nil
+	}
+
+	if strings.HasSuffix(raw, "i") {
+		v, err := strconv.ParseInt(strings.TrimSuffix(raw, "i"), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer value %q: %w", raw, err)
+		}
+		return v, nil
+	}
+	if strings.HasSuffix(raw, "u") {
+		v, err := strconv.ParseUint(strings.TrimSuffix(raw, "u"), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid unsigned integer value %q: %w", raw, err)
+		}
+		return v, nil
+	}
+
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid float value %q: %w", raw, err)
+	}
+	return v, nil
+}
+
+// splitLineProtocolRaw splits s on unescaped occurrences of sep (and,
+// when quoteAware is set, only outside double-quoted spans), without
+// resolving any escape sequence: every `\x` is copied through verbatim,
+// including `\sep`, so that a later split pass over the same token can
+// still see and skip it. Callers unescape each returned token exactly
+// once, via lineProtocolUnescape, after all of its delimiters have been
+// resolved.
+func splitLineProtocolRaw(s string, sep rune, quoteAware bool) []string {
+	var parts []string
+	var cur strings.Builder
+	escaped := false
+	inQuotes := false
+	for _, r := range s {
+		if escaped {
+			cur.WriteRune('\\')
+			cur.WriteRune(r)
+			escaped = false
+			continue
+		}
+		switch {
+		case r == '\\':
+			escaped = true
+		case quoteAware && r == '"':
+			inQuotes = !inQuotes
+			cur.WriteRune(r)
+		case r == sep && !inQuotes:
+			parts = append(parts, cur.String())
+			cur.Reset()
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	if escaped {
+		cur.WriteRune('\\')
+	}
+	parts = append(parts, cur.String())
+	return parts
+}
+
+func lineProtocolUnescape(s string) string {
+	if !strings.ContainsRune(s, '\\') {
+		return s
+	}
+
+	var b strings.Builder
+	escaped := false
+	for _, r := range s {
+		if escaped {
+			b.WriteRune(r)
+			escaped = false
+			continue
+		}
+		if r == '\\' {
+			escaped = true
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,76 @@
+// Copyright 2023 Greptime Team
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package greptime
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLineProtocol(t *testing.T) {
+	input := strings.Join([]string{
+		"# this is a comment",
+		"",
+		`weather,location=us-midwest,season=summer temperature=82i,humidity=0.6,desc="hot, dry",dry=t 1465839830100400200`,
+		`weather,location=us-midwest temperature=85i 1465839830100400300`,
+		`cpu,host=server01 value=1u`,
+	}, "\n")
+
+	requests, err := ParseLineProtocol(strings.NewReader(input), nil)
+	require.NoError(t, err)
+	require.Len(t, requests, 2)
+
+	weather := requests[0]
+	assert.Equal(t, "weather", weather.table)
+	require.Len(t, weather.GetSeries(), 2)
+
+	first := weather.GetSeries()[0]
+	assert.Equal(t, "us-midwest", first.vals["location"])
+	assert.Equal(t, "summer", first.vals["season"])
+	assert.Equal(t, int64(82), first.vals["temperature"])
+	assert.Equal(t, 0.6, first.vals["humidity"])
+	assert.Equal(t, "hot, dry", first.vals["desc"])
+	assert.Equal(t, true, first.vals["dry"])
+
+	second := weather.GetSeries()[1]
+	assert.Equal(t, "us-midwest", second.vals["location"])
+	assert.Equal(t, int64(85), second.vals["temperature"])
+	_, hasSeason := second.vals["season"]
+	assert.False(t, hasSeason)
+
+	cpu := requests[1]
+	assert.Equal(t, "cpu", cpu.table)
+	require.Len(t, cpu.GetSeries(), 1)
+	assert.Equal(t, uint64(1), cpu.GetSeries()[0].vals["value"])
+}
+
+func TestParseLineProtocolMissingFields(t *testing.T) {
+	_, err := ParseLineProtocol(strings.NewReader("weather,location=us-midwest"), nil)
+	assert.Error(t, err)
+}
+
+func TestParseLineProtocolEscapedTagValue(t *testing.T) {
+	requests, err := ParseLineProtocol(strings.NewReader(`weather,loc=us\=east,desc=cold\,dry temperature=40i`), nil)
+	require.NoError(t, err)
+	require.Len(t, requests, 1)
+
+	series := requests[0].GetSeries()
+	require.Len(t, series, 1)
+	assert.Equal(t, "us=east", series[0].vals["loc"])
+	assert.Equal(t, "cold,dry", series[0].vals["desc"])
+}
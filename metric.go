@@ -332,7 +332,7 @@ func (m *Metric) intoTimestampColumn() (*greptimepb.Column, error) {
 	}
 	tsColumn := &greptimepb.Column{
 		ColumnName:   m.GetTimestampAlias(),
-		SemanticType: greptimepb.Column_TIMESTAMP,
+		SemanticType: greptimepb.SemanticType_TIMESTAMP,
 		Datatype:     datatype,
 		Values:       &greptimepb.Column_Values{},
 		NullMask:     nil,
@@ -389,13 +389,13 @@ func setColumn(col *greptimepb.Column, val any) error {
 	case greptimepb.ColumnDataType_BINARY:
 		col.Values.BinaryValues = append(col.Values.BinaryValues, val.([]byte))
 	case greptimepb.ColumnDataType_TIMESTAMP_SECOND:
-		col.Values.TsSecondValues = append(col.Values.TsSecondValues, val.(int64))
+		col.Values.TimestampSecondValues = append(col.Values.TimestampSecondValues, val.(int64))
 	case greptimepb.ColumnDataType_TIMESTAMP_MILLISECOND:
-		col.Values.TsMillisecondValues = append(col.Values.TsMillisecondValues, val.(int64))
+		col.Values.TimestampMillisecondValues = append(col.Values.TimestampMillisecondValues, val.(int64))
 	case greptimepb.ColumnDataType_TIMESTAMP_MICROSECOND:
-		col.Values.TsMicrosecondValues = append(col.Values.TsMicrosecondValues, val.(int64))
+		col.Values.TimestampMicrosecondValues = append(col.Values.TimestampMicrosecondValues, val.(int64))
 	case greptimepb.ColumnDataType_TIMESTAMP_NANOSECOND:
-		col.Values.TsNanosecondValues = append(col.Values.TsNanosecondValues, val.(int64))
+		col.Values.TimestampNanosecondValues = append(col.Values.TimestampNanosecondValues, val.(int64))
 	default:
 		return fmt.Errorf("unknown column data type: %v", col.Datatype)
 	}
@@ -0,0 +1,182 @@
+// Copyright 2023 Greptime Team
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package greptime
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+)
+
+const (
+	prometheusNameLabel  = "__name__"
+	prometheusValueField = "value"
+)
+
+// FromPrometheusWriteRequest converts a Prometheus remote_write request
+// into one InsertRequest per distinct metric name (the `__name__` label),
+// the same table-per-measurement grouping ParseLineProtocol uses. The
+// remaining labels of a TimeSeries become TAG columns, its value becomes a
+// FLOAT64 FIELD column, and exemplars sharing a sample's timestamp are
+// attached as additional fields on that row. Series in the same batch
+// exposing different label sets are merged via Metric.AddSeries' union
+// semantics, which null-pads the columns they don't share.
+func FromPrometheusWriteRequest(req *prompb.WriteRequest) ([]*InsertRequest, error) {
+	if req == nil {
+		return nil, fmt.Errorf("write request should not be nil")
+	}
+
+	requests := map[string]*InsertRequest{}
+	var order []string
+
+	for _, ts := range req.Timeseries {
+		table, tags, err := splitPrometheusLabels(ts.Labels)
+		if err != nil {
+			return nil, err
+		}
+
+		insertReq, seen := requests[table]
+		if !seen {
+			insertReq = (&InsertRequest{}).WithTable(table)
+			if err := insertReq.SetTimePrecision(time.Millisecond); err != nil {
+				return nil, err
+			}
+			requests[table] = insertReq
+			order = append(order, table)
+		}
+
+		for _, sample := range ts.Samples {
+			series, err := prometheusSeries(tags, sample, ts.Exemplars)
+			if err != nil {
+				return nil, err
+			}
+			if err := insertReq.AddSeries(series); err != nil {
+				return nil, fmt.Errorf("table '%s': %w", table, err)
+			}
+		}
+	}
+
+	result := make([]*InsertRequest, 0, len(order))
+	for _, table := range order {
+		result = append(result, requests[table])
+	}
+	return result, nil
+}
+
+func prometheusSeries(tags []lineProtocolKV, sample prompb.Sample, exemplars []prompb.Exemplar) (Series, error) {
+	var series Series
+	for _, tag := range tags {
+		series.AddTag(tag.key, tag.value)
+	}
+	if err := series.AddField(prometheusValueField, sample.Value); err != nil {
+		return Series{}, err
+	}
+
+	for _, exemplar := range exemplars {
+		if exemplar.Timestamp != sample.Timestamp {
+			continue
+		}
+		if err := series.AddField("exemplar_value", exemplar.Value); err != nil {
+			return Series{}, err
+		}
+		for _, l := range exemplar.Labels {
+			name, err := toColumnName("exemplar_" + l.Name)
+			if err != nil {
+				return Series{}, err
+			}
+			if err := series.AddField(name, l.Value); err != nil {
+				return Series{}, err
+			}
+		}
+	}
+
+	series.SetTimestamp(time.UnixMilli(sample.Timestamp))
+	return series, nil
+}
+
+// splitPrometheusLabels pulls the `__name__` label out as the destination
+// table, validating it and every remaining label name via toColumnName.
+func splitPrometheusLabels(labels []prompb.Label) (string, []lineProtocolKV, error) {
+	var table string
+	tags := make([]lineProtocolKV, 0, len(labels))
+
+	for _, l := range labels {
+		if l.Name == prometheusNameLabel {
+			name, err := toColumnName(l.Value)
+			if err != nil {
+				return "", nil, err
+			}
+			table = name
+			continue
+		}
+
+		name, err := toColumnName(l.Name)
+		if err != nil {
+			return "", nil, err
+		}
+		tags = append(tags, lineProtocolKV{key: name, value: l.Value})
+	}
+
+	if isEmptyString(table) {
+		return "", nil, fmt.Errorf("missing '%s' label", prometheusNameLabel)
+	}
+	return table, tags, nil
+}
+
+// NewPrometheusRemoteWriteHandler returns an http.Handler that accepts
+// Prometheus remote_write requests and inserts them into database through
+// client. Mount it at the path configured as Prometheus' remote_write URL,
+// e.g. http.Handle("/api/v1/write", greptime.NewPrometheusRemoteWriteHandler(client, "public")).
+func NewPrometheusRemoteWriteHandler(client *Client, database string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		compressed, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		data, err := snappy.Decode(nil, compressed)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var writeReq prompb.WriteRequest
+		if err := writeReq.Unmarshal(data); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		requests, err := FromPrometheusWriteRequest(&writeReq)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		for _, req := range requests {
+			req.WithDatabase(database)
+			if _, err := client.Insert(r.Context(), req); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,85 @@
+// Copyright 2023 Greptime Team
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package greptime
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromPrometheusWriteRequest(t *testing.T) {
+	req := &prompb.WriteRequest{
+		Timeseries: []prompb.TimeSeries{
+			{
+				Labels: []prompb.Label{
+					{Name: "__name__", Value: "up"},
+					{Name: "instance", Value: "localhost:9090"},
+				},
+				Samples: []prompb.Sample{
+					{Value: 1, Timestamp: 1000},
+					{Value: 0, Timestamp: 2000},
+				},
+				Exemplars: []prompb.Exemplar{
+					{
+						Labels:    []prompb.Label{{Name: "trace_id", Value: "abc123"}},
+						Value:     1,
+						Timestamp: 1000,
+					},
+				},
+			},
+			{
+				Labels: []prompb.Label{
+					{Name: "__name__", Value: "cpu_usage"},
+				},
+				Samples: []prompb.Sample{{Value: 0.42, Timestamp: 1000}},
+			},
+		},
+	}
+
+	requests, err := FromPrometheusWriteRequest(req)
+	require.NoError(t, err)
+	require.Len(t, requests, 2)
+
+	up := requests[0]
+	assert.Equal(t, "up", up.table)
+	require.Len(t, up.GetSeries(), 2)
+	assert.Equal(t, "localhost:9090", up.GetSeries()[0].vals["instance"])
+	assert.Equal(t, 1.0, up.GetSeries()[0].vals["value"])
+	assert.Equal(t, "abc123", up.GetSeries()[0].vals["exemplar_trace_id"])
+	_, hasExemplar := up.GetSeries()[1].vals["exemplar_value"]
+	assert.False(t, hasExemplar)
+
+	cpu := requests[1]
+	assert.Equal(t, "cpu_usage", cpu.table)
+	require.Len(t, cpu.GetSeries(), 1)
+	assert.Equal(t, 0.42, cpu.GetSeries()[0].vals["value"])
+}
+
+func TestFromPrometheusWriteRequestMissingName(t *testing.T) {
+	req := &prompb.WriteRequest{
+		Timeseries: []prompb.TimeSeries{
+			{
+				Labels:  []prompb.Label{{Name: "instance", Value: "localhost:9090"}},
+				Samples: []prompb.Sample{{Value: 1, Timestamp: 1000}},
+			},
+		},
+	}
+
+	_, err := FromPrometheusWriteRequest(req)
+	assert.Error(t, err)
+}
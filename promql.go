@@ -0,0 +1,94 @@
+// Copyright 2023 Greptime Team
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package greptime
+
+import (
+	"strconv"
+	"time"
+
+	greptimepb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+)
+
+// InstantPromql represents a PromQL instant query, evaluated at a single
+// point in time.
+type InstantPromql struct {
+	Query string
+	Time  time.Time
+}
+
+// NewInstantPromql creates an InstantPromql evaluated at time.Now() once
+// built. Set Time explicitly to evaluate at a different instant.
+func NewInstantPromql(query string) *InstantPromql {
+	return &InstantPromql{Query: query}
+}
+
+func (p *InstantPromql) build() (*greptimepb.PromInstantQuery, error) {
+	if isEmptyString(p.Query) {
+		return nil, ErrEmptyPromql
+	}
+
+	t := p.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+
+	return &greptimepb.PromInstantQuery{
+		Query: p.Query,
+		Time:  formatPromqlTimestamp(t),
+	}, nil
+}
+
+// RangePromql represents a PromQL range query, evaluated over [Start, End]
+// at Step intervals.
+type RangePromql struct {
+	Query string
+	Start time.Time
+	End   time.Time
+	Step  time.Duration
+}
+
+func (p *RangePromql) build() (*greptimepb.PromRangeQuery, error) {
+	if isEmptyString(p.Query) {
+		return nil, ErrEmptyPromql
+	}
+	if p.Start.IsZero() || p.End.IsZero() {
+		return nil, ErrEmptyRange
+	}
+	if p.Step <= 0 {
+		return nil, ErrEmptyStep
+	}
+
+	return &greptimepb.PromRangeQuery{
+		Query: p.Query,
+		Start: formatPromqlTimestamp(p.Start),
+		End:   formatPromqlTimestamp(p.End),
+		Step:  strconv.FormatFloat(p.Step.Seconds(), 'f', -1, 64),
+	}, nil
+}
+
+// formatPromqlTimestamp formats t the same way the Prometheus HTTP API
+// does: fractional seconds since the Unix epoch.
+func formatPromqlTimestamp(t time.Time) string {
+	return strconv.FormatFloat(float64(t.UnixNano())/float64(time.Second), 'f', -1, 64)
+}
+
+// PromqlResult is the result of Client.PromqlInstant. GreptimeDB's
+// PrometheusGateway responds with the exact same JSON body Prometheus's own
+// HTTP API would for the equivalent query, so Body is left undecoded rather
+// than forced into Metric's Arrow-derived shape; callers already using a
+// Prometheus HTTP API client can decode it the same way they decode that.
+type PromqlResult struct {
+	Body []byte
+}
@@ -0,0 +1,170 @@
+// Copyright 2023 Greptime Team
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package greptime
+
+import (
+	greptimepb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+)
+
+// QueryRequest helps to build a request to query data out of GreptimeDB.
+// Sql, InstantPromql and RangePromql are exclusive to each other.
+type QueryRequest struct {
+	header
+
+	sql string
+
+	instantPromql *InstantPromql
+	rangePromql   *RangePromql
+}
+
+// WithDatabase specifies the database to query.
+func (r *QueryRequest) WithDatabase(database string) *QueryRequest {
+	r.database = database
+	return r
+}
+
+// WithSql specifies the sql to query.
+func (r *QueryRequest) WithSql(sql string) *QueryRequest {
+	r.sql = sql
+	return r
+}
+
+// WithInstantPromql specifies an InstantPromql to query.
+func (r *QueryRequest) WithInstantPromql(promql *InstantPromql) *QueryRequest {
+	r.instantPromql = promql
+	return r
+}
+
+// WithRangePromql specifies a RangePromql to query.
+func (r *QueryRequest) WithRangePromql(promql *RangePromql) *QueryRequest {
+	r.rangePromql = promql
+	return r
+}
+
+// buildGreptimeRequest builds the GreptimeRequest accepted by the general
+// Handle rpc. Sql and RangePromql both go through this same Flight-ticket
+// path, since GreptimeDB's QueryRequest oneof can carry either. InstantPromql
+// cannot: it has no place in that oneof, and is only ever sent through the
+// dedicated PrometheusGateway.Handle rpc by Client.PromqlInstant.
+func (r *QueryRequest) buildGreptimeRequest(cfg *Config) (*greptimepb.GreptimeRequest, error) {
+	header, err := r.header.build(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	query := greptimepb.QueryRequest{}
+
+	switch {
+	case !isEmptyString(r.sql):
+		query.Query = &greptimepb.QueryRequest_Sql{Sql: r.sql}
+	case r.rangePromql != nil:
+		rangeQuery, err := r.rangePromql.build()
+		if err != nil {
+			return nil, err
+		}
+		query.Query = &greptimepb.QueryRequest_PromRangeQuery{PromRangeQuery: rangeQuery}
+	case r.instantPromql != nil:
+		return nil, ErrInstantPromqlUnsupported
+	default:
+		return nil, ErrEmptyQuery
+	}
+
+	return &greptimepb.GreptimeRequest{
+		Header: header,
+		Request: &greptimepb.GreptimeRequest_Query{
+			Query: &query,
+		},
+	}, nil
+}
+
+// buildPromqlRequest builds the PromqlRequest sent to PrometheusGateway.Handle
+// for Client.PromqlInstant. Unlike buildGreptimeRequest, Sql and RangePromql
+// are rejected here: this path only ever carries an InstantPromql query.
+func (r *QueryRequest) buildPromqlRequest(cfg *Config) (*greptimepb.PromqlRequest, error) {
+	header, err := r.header.build(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isEmptyString(r.sql) || r.rangePromql != nil {
+		return nil, ErrSqlInPromql
+	}
+	if r.instantPromql == nil {
+		return nil, ErrEmptyQuery
+	}
+
+	instantQuery, err := r.instantPromql.build()
+	if err != nil {
+		return nil, err
+	}
+
+	return &greptimepb.PromqlRequest{
+		Header: header,
+		Promql: &greptimepb.PromqlRequest_InstantQuery{InstantQuery: instantQuery},
+	}, nil
+}
+
+// InsertRequest helps to build a request to insert a Metric's rows into a
+// table.
+type InsertRequest struct {
+	header
+
+	Metric
+
+	table string
+}
+
+// WithDatabase specifies the database to insert into.
+func (r *InsertRequest) WithDatabase(database string) *InsertRequest {
+	r.database = database
+	return r
+}
+
+// WithTable specifies the table to insert into.
+func (r *InsertRequest) WithTable(table string) *InsertRequest {
+	r.table = table
+	return r
+}
+
+func (r *InsertRequest) build(cfg *Config) (*greptimepb.GreptimeRequest, error) {
+	header, err := r.header.build(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if isEmptyString(r.table) {
+		return nil, ErrEmptyTable
+	}
+
+	columns, err := r.intoGreptimeColumn()
+	if err != nil {
+		return nil, err
+	}
+
+	return &greptimepb.GreptimeRequest{
+		Header: header,
+		Request: &greptimepb.GreptimeRequest_Inserts{
+			Inserts: &greptimepb.InsertRequests{
+				Inserts: []*greptimepb.InsertRequest{
+					{
+						TableName: r.table,
+						Columns:   columns,
+						RowCount:  uint32(len(r.series)),
+					},
+				},
+			},
+		},
+	}, nil
+}
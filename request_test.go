@@ -38,11 +38,12 @@ func TestQueryBuildGreptimeRequest(t *testing.T) {
 	assert.NotNil(t, request)
 	assert.Nil(t, err)
 
-	// test instant promql
-	rb.WithInstantPromql(NewInstantPromql("up == 0"))
+	// test instant promql: GreptimeDB's QueryRequest has no variant for it,
+	// so it can only be run through Client.PromqlInstant.
+	rb = (&QueryRequest{}).WithDatabase("disk_usage").WithInstantPromql(NewInstantPromql("up == 0"))
 	request, err = rb.buildGreptimeRequest(&Config{})
 	assert.Nil(t, request)
-	assert.ErrorIs(t, err, ErrNotImplemented)
+	assert.ErrorIs(t, err, ErrInstantPromqlUnsupported)
 
 	// test range promql
 	rp := &RangePromql{
@@ -51,7 +52,7 @@ func TestQueryBuildGreptimeRequest(t *testing.T) {
 		End:   time.Now(),
 		Step:  time.Second * 10,
 	}
-	rb.WithRangePromql(rp)
+	rb = (&QueryRequest{}).WithDatabase("disk_usage").WithRangePromql(rp)
 	request, err = rb.buildGreptimeRequest(&Config{})
 	assert.NotNil(t, request)
 	assert.Nil(t, err)
@@ -75,22 +76,22 @@ func TestQueryBuildPromqlRequest(t *testing.T) {
 	assert.ErrorIs(t, err, ErrSqlInPromql)
 
 	// test instant promql
-	rb.WithInstantPromql(NewInstantPromql("up == 0"))
+	rb = (&QueryRequest{}).WithDatabase("disk_usage").WithInstantPromql(NewInstantPromql("up == 0"))
 	request, err = rb.buildPromqlRequest(&Config{})
 	assert.NotNil(t, request)
 	assert.Nil(t, err)
 
-	// test range promql
+	// test range promql: this path only ever carries InstantPromql.
 	rp := &RangePromql{
 		Query: "up == 0",
 		Start: time.Now(),
 		End:   time.Now(),
 		Step:  time.Second * 10,
 	}
-	rb.WithRangePromql(rp)
+	rb = (&QueryRequest{}).WithDatabase("disk_usage").WithRangePromql(rp)
 	request, err = rb.buildPromqlRequest(&Config{})
-	assert.NotNil(t, request)
-	assert.Nil(t, err)
+	assert.Nil(t, request)
+	assert.ErrorIs(t, err, ErrSqlInPromql)
 }
 
 func TestInsertBuilder(t *testing.T) {
@@ -0,0 +1,107 @@
+// Copyright 2023 Greptime Team
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package greptime
+
+import (
+	"fmt"
+	"time"
+
+	greptimepb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+)
+
+// Series represents one row of data that will become part of a Metric.
+type Series struct {
+	timestamp time.Time
+
+	// orders and columns/vals SHOULD NOT contain the timestamp column
+	orders  []string
+	columns map[string]column
+	vals    map[string]any
+}
+
+func (s *Series) ensure() {
+	if s.columns == nil {
+		s.columns = map[string]column{}
+	}
+	if s.vals == nil {
+		s.vals = map[string]any{}
+	}
+}
+
+// SetTimestamp sets the timestamp of this row.
+func (s *Series) SetTimestamp(t time.Time) {
+	s.timestamp = t
+}
+
+func (s *Series) set(name string, col column, val any) {
+	s.ensure()
+	if _, seen := s.columns[name]; !seen {
+		s.orders = append(s.orders, name)
+	}
+	s.columns[name] = col
+	s.vals[name] = val
+}
+
+// AddTag adds a column with TAG semantic type. Tags are always encoded as
+// strings.
+func (s *Series) AddTag(name, val string) {
+	s.set(name, column{greptimepb.SemanticType_TAG, greptimepb.ColumnDataType_STRING}, val)
+}
+
+// AddField adds a column with FIELD semantic type, inferring the wire
+// datatype from the Go type of val.
+func (s *Series) AddField(name string, val any) error {
+	typ, err := fieldDataType(val)
+	if err != nil {
+		return err
+	}
+	s.set(name, column{greptimepb.SemanticType_FIELD, typ}, val)
+	return nil
+}
+
+// fieldDataType infers the ColumnDataType to use for a FIELD column from
+// the Go type of val.
+func fieldDataType(val any) (greptimepb.ColumnDataType, error) {
+	switch val.(type) {
+	case int8:
+		return greptimepb.ColumnDataType_INT8, nil
+	case int16:
+		return greptimepb.ColumnDataType_INT16, nil
+	case int32:
+		return greptimepb.ColumnDataType_INT32, nil
+	case int64:
+		return greptimepb.ColumnDataType_INT64, nil
+	case uint8:
+		return greptimepb.ColumnDataType_UINT8, nil
+	case uint16:
+		return greptimepb.ColumnDataType_UINT16, nil
+	case uint32:
+		return greptimepb.ColumnDataType_UINT32, nil
+	case uint64:
+		return greptimepb.ColumnDataType_UINT64, nil
+	case float32:
+		return greptimepb.ColumnDataType_FLOAT32, nil
+	case float64:
+		return greptimepb.ColumnDataType_FLOAT64, nil
+	case bool:
+		return greptimepb.ColumnDataType_BOOLEAN, nil
+	case string:
+		return greptimepb.ColumnDataType_STRING, nil
+	case []byte:
+		return greptimepb.ColumnDataType_BINARY, nil
+	default:
+		return 0, fmt.Errorf("unsupported field type '%T'", val)
+	}
+}
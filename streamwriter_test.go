@@ -0,0 +1,79 @@
+// Copyright 2023 Greptime Team
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package greptime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tsField(name string) arrow.Field {
+	md := arrow.NewMetadata([]string{"greptime:time_index"}, []string{"true"})
+	return arrow.Field{
+		Name:     name,
+		Type:     &arrow.TimestampType{Unit: arrow.Millisecond},
+		Metadata: md,
+	}
+}
+
+func TestAppendFieldValue(t *testing.T) {
+	alloc := memory.NewGoAllocator()
+
+	tsBuilder := array.NewBuilder(alloc, &arrow.TimestampType{Unit: arrow.Millisecond})
+	defer tsBuilder.Release()
+
+	var s Series
+	s.SetTimestamp(time.UnixMilli(1000))
+	require.NoError(t, s.AddField("cpu", 0.5))
+
+	require.NoError(t, appendFieldValue(tsBuilder, tsField("ts"), s))
+	arr := tsBuilder.NewArray()
+	defer arr.Release()
+	ts, ok := arr.(*array.Timestamp)
+	require.True(t, ok)
+	assert.Equal(t, arrow.Timestamp(1000), ts.Value(0))
+
+	floatBuilder := array.NewBuilder(alloc, arrow.PrimitiveTypes.Float64)
+	defer floatBuilder.Release()
+	field := arrow.Field{Name: "cpu", Type: arrow.PrimitiveTypes.Float64}
+	require.NoError(t, appendFieldValue(floatBuilder, field, s))
+	farr := floatBuilder.NewArray()
+	defer farr.Release()
+	fv, ok := farr.(*array.Float64)
+	require.True(t, ok)
+	assert.Equal(t, 0.5, fv.Value(0))
+}
+
+func TestAppendFieldValueMissingIsNull(t *testing.T) {
+	alloc := memory.NewGoAllocator()
+	b := array.NewBuilder(alloc, arrow.PrimitiveTypes.Float64)
+	defer b.Release()
+
+	var s Series
+	s.SetTimestamp(time.UnixMilli(1000))
+
+	field := arrow.Field{Name: "missing", Type: arrow.PrimitiveTypes.Float64}
+	require.NoError(t, appendFieldValue(b, field, s))
+
+	arr := b.NewArray()
+	defer arr.Release()
+	assert.True(t, arr.IsNull(0))
+}
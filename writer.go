@@ -0,0 +1,380 @@
+// Copyright 2023 Greptime Team
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package greptime
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// WriterOptions configures a Writer's batching, concurrency and retry
+// behavior.
+type WriterOptions struct {
+	// BatchSize flushes the buffer once it holds this many rows.
+	BatchSize int
+	// BatchBytes flushes the buffer once its estimated size in bytes
+	// reaches this threshold.
+	BatchBytes int
+	// FlushInterval flushes the buffer on a timer, even if neither
+	// threshold above has been reached.
+	FlushInterval time.Duration
+	// Concurrency bounds how many batches may be in flight to GreptimeDB
+	// at once.
+	Concurrency int
+	// NewBackOff creates the retry policy used for one batch. Defaults to
+	// NewExponentialBackOff.
+	NewBackOff func() BackOff
+	// OnError, if set, is called whenever a batch is dropped: after
+	// exhausting its BackOff, on a non-retriable error, or when the ctx of
+	// the flush queuing it is done first.
+	OnError func(error)
+}
+
+// NewWriterOptions creates WriterOptions with Telegraf-agent-like
+// defaults: 1000 rows or 1 second, whichever comes first, with up to 4
+// batches in flight.
+func NewWriterOptions() *WriterOptions {
+	return &WriterOptions{
+		BatchSize:     1000,
+		BatchBytes:    1 << 20,
+		FlushInterval: time.Second,
+		Concurrency:   4,
+		NewBackOff:    func() BackOff { return NewExponentialBackOff() },
+	}
+}
+
+// WithBatchSize overrides BatchSize.
+func (o *WriterOptions) WithBatchSize(n int) *WriterOptions {
+	o.BatchSize = n
+	return o
+}
+
+// WithBatchBytes overrides BatchBytes.
+func (o *WriterOptions) WithBatchBytes(n int) *WriterOptions {
+	o.BatchBytes = n
+	return o
+}
+
+// WithFlushInterval overrides FlushInterval.
+func (o *WriterOptions) WithFlushInterval(d time.Duration) *WriterOptions {
+	o.FlushInterval = d
+	return o
+}
+
+// WithConcurrency overrides Concurrency.
+func (o *WriterOptions) WithConcurrency(n int) *WriterOptions {
+	o.Concurrency = n
+	return o
+}
+
+// WithOnError sets the OnError callback.
+func (o *WriterOptions) WithOnError(f func(error)) *WriterOptions {
+	o.OnError = f
+	return o
+}
+
+// Writer batches rows for a single table in memory and flushes them to
+// GreptimeDB asynchronously, so producers that cannot block on synchronous
+// inserts have somewhere to push data. It retries a flushed batch with
+// exponential backoff on retriable gRPC errors (Unavailable,
+// ResourceExhausted, DeadlineExceeded) and drops it, reporting via
+// OnError, once the backoff is exhausted.
+type Writer struct {
+	client   *Client
+	database string
+	table    string
+	opts     *WriterOptions
+
+	mu            sync.Mutex
+	buffer        Metric
+	bufferedRows  int
+	bufferedBytes int
+	stopped       bool
+
+	queue    chan *InsertRequest
+	workerWg sync.WaitGroup
+	inFlight sync.WaitGroup
+
+	flushTimer *time.Timer
+	closeOnce  sync.Once
+	closed     chan struct{}
+
+	droppedBatches uint64
+}
+
+// errWriterClosed is returned by WritePoint/Write once Close has stopped the
+// writer, which happens after Close's own final Flush.
+var errWriterClosed = errors.New("greptime: writer is closed")
+
+// NewWriter creates a Writer that flushes into database.table.
+func (c *Client) NewWriter(database, table string, opts *WriterOptions) *Writer {
+	if opts == nil {
+		opts = NewWriterOptions()
+	}
+	if opts.NewBackOff == nil {
+		opts.NewBackOff = func() BackOff { return NewExponentialBackOff() }
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+
+	w := &Writer{
+		client:     c,
+		database:   database,
+		table:      table,
+		opts:       opts,
+		queue:      make(chan *InsertRequest, opts.Concurrency),
+		flushTimer: time.NewTimer(opts.FlushInterval),
+		closed:     make(chan struct{}),
+	}
+	if err := w.buffer.SetTimestampAlias("ts"); err != nil {
+		// "ts" is always a valid alias; this can never happen.
+		panic(err)
+	}
+
+	w.workerWg.Add(opts.Concurrency)
+	for i := 0; i < opts.Concurrency; i++ {
+		go w.worker()
+	}
+	go w.timerLoop()
+
+	return w
+}
+
+// DroppedBatches returns the number of batches dropped so far, for any of
+// the reasons OnError is called for.
+func (w *Writer) DroppedBatches() uint64 {
+	return atomic.LoadUint64(&w.droppedBatches)
+}
+
+// Write buffers every Series of m, flushing once BatchSize or BatchBytes
+// is reached. Like WritePoint, it can block on ctx if that flush races
+// GreptimeDB backpressure.
+func (w *Writer) Write(ctx context.Context, m *Metric) error {
+	for _, s := range m.GetSeries() {
+		if err := w.WritePoint(ctx, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WritePoint buffers a single Series, flushing once BatchSize or
+// BatchBytes is reached. Buffering itself never blocks, but the flush it
+// triggers sends the batch to a queue only opts.Concurrency deep: if every
+// worker is busy (or retrying a failed send through its BackOff),
+// WritePoint blocks until a slot frees up or ctx is done, whichever comes
+// first.
+func (w *Writer) WritePoint(ctx context.Context, s Series) error {
+	w.mu.Lock()
+	if w.stopped {
+		w.mu.Unlock()
+		return errWriterClosed
+	}
+	if err := w.buffer.AddSeries(s); err != nil {
+		w.mu.Unlock()
+		return err
+	}
+	w.bufferedRows++
+	w.bufferedBytes += seriesByteSize(s)
+
+	shouldFlush := w.bufferedRows >= w.opts.BatchSize || w.bufferedBytes >= w.opts.BatchBytes
+	w.mu.Unlock()
+
+	if shouldFlush {
+		return w.flush(ctx)
+	}
+	return nil
+}
+
+// Flush blocks until every buffered row, and every batch already in
+// flight, has been sent (or permanently dropped), or ctx is done.
+func (w *Writer) Flush(ctx context.Context) error {
+	if err := w.flush(ctx); err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		w.inFlight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close flushes any remaining data, stops accepting new batches and waits
+// for in-flight workers to finish, or for ctx to be done.
+func (w *Writer) Close(ctx context.Context) error {
+	err := w.Flush(ctx)
+
+	w.closeOnce.Do(func() {
+		w.flushTimer.Stop()
+		close(w.closed)
+
+		// Flip stopped and close the queue under w.mu, the same lock flush
+		// holds for its entire body (including the send to w.queue). That
+		// makes the two mutually exclusive: either flush finishes its send
+		// before Close observes the lock, or Close closes the queue first
+		// and flush sees w.stopped and returns without ever touching a
+		// closed channel.
+		w.mu.Lock()
+		w.stopped = true
+		close(w.queue)
+		w.mu.Unlock()
+	})
+
+	done := make(chan struct{})
+	go func() {
+		w.workerWg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return err
+	case <-ctx.Done():
+		if err == nil {
+			err = ctx.Err()
+		}
+		return err
+	}
+}
+
+func (w *Writer) timerLoop() {
+	for {
+		select {
+		case <-w.flushTimer.C:
+			// The timer has no caller-supplied ctx to honor; a timer-driven
+			// flush backs off only if the writer itself is closing.
+			_ = w.flush(context.Background())
+			w.flushTimer.Reset(w.opts.FlushInterval)
+		case <-w.closed:
+			return
+		}
+	}
+}
+
+// flush moves the current buffer onto the send queue, if it holds any
+// rows, blocking until a slot frees up or ctx is done. It holds w.mu for
+// the whole operation, including the send to w.queue, so it can never
+// race Close's close(w.queue): Close takes the same lock to flip
+// w.stopped and close the queue, so flush either completes its send
+// first or observes w.stopped and backs off before ever sending on a
+// channel that might already be closed.
+func (w *Writer) flush(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.stopped || w.bufferedRows == 0 {
+		return nil
+	}
+
+	batch := w.buffer
+	w.buffer = Metric{}
+	_ = w.buffer.SetTimestampAlias(batch.GetTimestampAlias())
+	w.bufferedRows = 0
+	w.bufferedBytes = 0
+
+	req := (&InsertRequest{Metric: batch}).WithDatabase(w.database).WithTable(w.table)
+
+	w.inFlight.Add(1)
+	select {
+	case w.queue <- req:
+		return nil
+	case <-ctx.Done():
+		w.inFlight.Done()
+		w.drop(ctx.Err())
+		return ctx.Err()
+	}
+}
+
+func (w *Writer) worker() {
+	defer w.workerWg.Done()
+	for req := range w.queue {
+		w.send(req)
+		w.inFlight.Done()
+	}
+}
+
+// send delivers req, retrying on retriable gRPC errors according to
+// opts.NewBackOff until it succeeds or the BackOff is exhausted.
+func (w *Writer) send(req *InsertRequest) {
+	backOff := w.opts.NewBackOff()
+
+	for {
+		_, err := w.client.Insert(context.Background(), req)
+		if err == nil {
+			return
+		}
+
+		if !isRetriableError(err) {
+			w.drop(err)
+			return
+		}
+
+		wait := backOff.NextBackOff()
+		if wait == Stop {
+			w.drop(err)
+			return
+		}
+		time.Sleep(wait)
+	}
+}
+
+func (w *Writer) drop(err error) {
+	atomic.AddUint64(&w.droppedBatches, 1)
+	if w.opts.OnError != nil {
+		w.opts.OnError(err)
+	}
+}
+
+func isRetriableError(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.ResourceExhausted, codes.DeadlineExceeded:
+		return true
+	default:
+		return false
+	}
+}
+
+// seriesByteSize is a rough estimate of a Series' wire size, used only to
+// decide when to flush.
+func seriesByteSize(s Series) int {
+	size := 8 // timestamp
+	for name, val := range s.vals {
+		size += len(name)
+		switch v := val.(type) {
+		case string:
+			size += len(v)
+		case []byte:
+			size += len(v)
+		default:
+			size += 8
+		}
+	}
+	return size
+}
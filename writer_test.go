@@ -0,0 +1,114 @@
+// Copyright 2023 Greptime Team
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package greptime
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriterOptions(t *testing.T) {
+	opts := NewWriterOptions().
+		WithBatchSize(10).
+		WithBatchBytes(1024).
+		WithFlushInterval(time.Minute).
+		WithConcurrency(2)
+
+	assert.Equal(t, 10, opts.BatchSize)
+	assert.Equal(t, 1024, opts.BatchBytes)
+	assert.Equal(t, time.Minute, opts.FlushInterval)
+	assert.Equal(t, 2, opts.Concurrency)
+}
+
+func TestSeriesByteSize(t *testing.T) {
+	var s Series
+	s.AddTag("host", "server01")
+	assert.NoError(t, s.AddField("value", 1.5))
+
+	assert.Greater(t, seriesByteSize(s), 0)
+}
+
+// TestWriterWritePointHonorsContext checks that WritePoint gives up on a
+// blocked flush once its ctx is done, rather than stalling for as long as
+// GreptimeDB (or the configured BackOff) takes.
+func TestWriterWritePointHonorsContext(t *testing.T) {
+	w := &Writer{
+		database:   "db",
+		table:      "t",
+		opts:       NewWriterOptions().WithConcurrency(1).WithBatchSize(1),
+		queue:      make(chan *InsertRequest), // unbuffered, nothing ever drains it
+		flushTimer: time.NewTimer(time.Hour),
+		closed:     make(chan struct{}),
+	}
+	require.NoError(t, w.buffer.SetTimestampAlias("ts"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var s Series
+	s.SetTimestamp(time.Now())
+	require.NoError(t, s.AddField("v", 1.0))
+
+	err := w.WritePoint(ctx, s)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, uint64(1), w.DroppedBatches())
+}
+
+// TestWriterCloseFlushRace exercises WritePoint (which calls flush once
+// BatchSize is reached) racing against Close. Before flush/Close shared
+// the w.stopped flag under w.mu, this could panic with "send on closed
+// channel" when flush's select happened to pick the send case even after
+// Close had closed w.queue.
+func TestWriterCloseFlushRace(t *testing.T) {
+	w := &Writer{
+		database:   "db",
+		table:      "t",
+		opts:       NewWriterOptions().WithConcurrency(2).WithBatchSize(1),
+		queue:      make(chan *InsertRequest, 2),
+		flushTimer: time.NewTimer(time.Hour),
+		closed:     make(chan struct{}),
+	}
+	require.NoError(t, w.buffer.SetTimestampAlias("ts"))
+
+	w.workerWg.Add(1)
+	go func() {
+		defer w.workerWg.Done()
+		for range w.queue {
+			w.inFlight.Done()
+		}
+	}()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			var s Series
+			s.SetTimestamp(time.Now())
+			_ = s.AddField("v", float64(i))
+			_ = w.WritePoint(context.Background(), s)
+		}
+	}()
+
+	assert.NotPanics(t, func() {
+		require.NoError(t, w.Close(context.Background()))
+	})
+	wg.Wait()
+}